@@ -0,0 +1,143 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// pushTargetSecret writes data, and labels/annotations, onto the remote
+// Secret identified by key, using the write strategy selected by
+// pushMeta.ApplyMode. sourceLabels/sourceAnnotations is mergeSourceMetadata's
+// output - the keys this PushSecret itself contributes, independent of
+// whatever else already exists on the remote:
+//   - Update: a full client.Update of the fetched remote Secret with
+//     targetLabels/targetAnnotations, computed by merging sourceLabels/
+//     sourceAnnotations onto that same fetch via mergeTargetMetadata per the
+//     target merge policy - the legacy last-writer-wins behavior.
+//   - Patch: the two-way strategic merge patch built by buildTargetPatch,
+//     retried on optimistic-lock conflicts. Each retry re-fetches the remote
+//     Secret and re-runs mergeTargetMetadata against that fresh copy rather
+//     than reusing labels/annotations computed before the conflict, so a key
+//     a concurrent writer added between our original fetch and the conflict
+//     is preserved instead of being diffed away as "missing from desired".
+//   - ServerSideApply: an unstructured apply object containing only
+//     sourceLabels/sourceAnnotations, submitted via client.Apply so disjoint
+//     owners of the same Secret never step on each other.
+func pushTargetSecret(ctx context.Context, c client.Client, key client.ObjectKey, pushMeta *PushSecretMetadata, policy targetMergePolicy, sourceLabels, sourceAnnotations map[string]string, data map[string][]byte) error {
+	mode := applyModePatch
+	if pushMeta != nil && pushMeta.Spec.ApplyMode != "" {
+		mode = pushMeta.Spec.ApplyMode
+	}
+
+	switch mode {
+	case applyModeUpdate:
+		remoteSecret := &v1.Secret{}
+		if err := c.Get(ctx, key, remoteSecret); err != nil {
+			return fmt.Errorf("failed to get remote secret %s: %w", key, err)
+		}
+		targetLabels, targetAnnotations, err := mergeTargetMetadata(remoteSecret, pushMeta, sourceLabels, sourceAnnotations)
+		if err != nil {
+			return err
+		}
+		remoteSecret.ObjectMeta.Labels = targetLabels
+		remoteSecret.ObjectMeta.Annotations = targetAnnotations
+		remoteSecret.Data = data
+		if err := c.Update(ctx, remoteSecret); err != nil {
+			return fmt.Errorf("failed to update remote secret %s: %w", key, err)
+		}
+		return nil
+	case applyModePatch:
+		return patchRemoteSecret(ctx, c, key, func(remoteSecret *v1.Secret) (client.Patch, error) {
+			targetLabels, targetAnnotations, err := mergeTargetMetadata(remoteSecret, pushMeta, sourceLabels, sourceAnnotations)
+			if err != nil {
+				return nil, err
+			}
+			return buildTargetPatch(remoteSecret, policy, targetLabels, targetAnnotations, data)
+		})
+	case applyModeServerSideApply:
+		return applyTargetSecret(ctx, c, key, pushMeta, policy, sourceLabels, sourceAnnotations, data)
+	default:
+		return fmt.Errorf("unexpected apply mode %q", mode)
+	}
+}
+
+// applyTargetSecret builds an unstructured object containing only the fields
+// this PushSecret owns (ownedLabels/ownedAnnotations, i.e. mergeSourceMetadata's
+// output, never the remote-merged result) and submits it as a server-side
+// apply, so that multiple PushSecrets (or a human operator) can co-own
+// disjoint keys of the same target Secret.
+func applyTargetSecret(ctx context.Context, c client.Client, key client.ObjectKey, pushMeta *PushSecretMetadata, policy targetMergePolicy, ownedLabels, ownedAnnotations map[string]string, data map[string][]byte) error {
+	fieldManager := defaultFieldManager
+	force := false
+	if pushMeta != nil {
+		if pushMeta.Spec.FieldManager != "" {
+			fieldManager = pushMeta.Spec.FieldManager
+		}
+		force = pushMeta.Spec.Force
+	}
+
+	obj, err := buildApplyObject(key, policy, ownedLabels, ownedAnnotations, data)
+	if err != nil {
+		return err
+	}
+
+	opts := []client.PatchOption{client.FieldOwner(fieldManager)}
+	if force {
+		opts = append(opts, client.ForceOwnership)
+	}
+
+	if err := c.Patch(ctx, obj, client.Apply, opts...); err != nil {
+		if apierrors.IsConflict(err) {
+			return fmt.Errorf("server-side apply conflict for secret %s (field manager %q): %w", key, fieldManager, err)
+		}
+		return fmt.Errorf("failed to apply remote secret %s: %w", key, err)
+	}
+	return nil
+}
+
+// buildApplyObject constructs the unstructured Secret an apply request should
+// carry: only ownedLabels/ownedAnnotations - the keys this PushSecret itself
+// owns - so SSA's "owned by nobody else" semantics apply field-by-field
+// instead of secret-wide. When policy is Ignore, metadata is omitted
+// entirely and only data is owned.
+func buildApplyObject(key client.ObjectKey, policy targetMergePolicy, ownedLabels, ownedAnnotations map[string]string, data map[string][]byte) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("Secret")
+	obj.SetName(key.Name)
+	obj.SetNamespace(key.Namespace)
+
+	if policy != targetMergePolicyIgnore {
+		obj.SetLabels(ownedLabels)
+		obj.SetAnnotations(ownedAnnotations)
+	}
+
+	stringData := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		stringData[k] = string(v)
+	}
+	if err := unstructured.SetNestedMap(obj.Object, stringData, "stringData"); err != nil {
+		return nil, fmt.Errorf("failed to build apply object for secret %s: %w", key, err)
+	}
+
+	return obj, nil
+}
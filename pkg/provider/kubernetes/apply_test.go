@@ -0,0 +1,84 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestBuildApplyObjectOwnsOnlySourceFields is a regression test: the apply
+// object must carry only the keys this PushSecret itself owns
+// (ownedLabels/ownedAnnotations), never the remote's pre-existing
+// labels/annotations - otherwise SSA claims ownership of fields it never set
+// and generates spurious conflicts with other managers.
+func TestBuildApplyObjectOwnsOnlySourceFields(t *testing.T) {
+	key := client.ObjectKey{Name: "target", Namespace: "default"}
+	ownedLabels := map[string]string{"app": "checkout"}
+	ownedAnnotations := map[string]string{"owner": "team-x"}
+	data := map[string][]byte{"password": []byte("hunter2")}
+
+	obj, err := buildApplyObject(key, targetMergePolicyMerge, ownedLabels, ownedAnnotations, data)
+	if err != nil {
+		t.Fatalf("buildApplyObject() error = %v", err)
+	}
+
+	if got := obj.GetLabels(); !reflect.DeepEqual(got, ownedLabels) {
+		t.Errorf("labels = %v, want only the owned labels %v", got, ownedLabels)
+	}
+	if got := obj.GetAnnotations(); !reflect.DeepEqual(got, ownedAnnotations) {
+		t.Errorf("annotations = %v, want only the owned annotations %v", got, ownedAnnotations)
+	}
+}
+
+func TestBuildApplyObjectIgnorePolicyOmitsMetadata(t *testing.T) {
+	key := client.ObjectKey{Name: "target", Namespace: "default"}
+	ownedLabels := map[string]string{"app": "checkout"}
+
+	obj, err := buildApplyObject(key, targetMergePolicyIgnore, ownedLabels, nil, map[string][]byte{"password": []byte("x")})
+	if err != nil {
+		t.Fatalf("buildApplyObject() error = %v", err)
+	}
+
+	if got := obj.GetLabels(); len(got) != 0 {
+		t.Errorf("labels = %v, want none under Ignore", got)
+	}
+	if got := obj.GetAnnotations(); len(got) != 0 {
+		t.Errorf("annotations = %v, want none under Ignore", got)
+	}
+}
+
+func TestBuildApplyObjectSetsStringData(t *testing.T) {
+	key := client.ObjectKey{Name: "target", Namespace: "default"}
+
+	obj, err := buildApplyObject(key, targetMergePolicyMerge, nil, nil, map[string][]byte{"password": []byte("hunter2")})
+	if err != nil {
+		t.Fatalf("buildApplyObject() error = %v", err)
+	}
+
+	stringData, found, err := unstructured.NestedStringMap(obj.Object, "stringData")
+	if err != nil {
+		t.Fatalf("failed to read stringData: %v", err)
+	}
+	if !found {
+		t.Fatalf("stringData not set on apply object")
+	}
+	if stringData["password"] != "hunter2" {
+		t.Errorf("stringData[password] = %q, want %q", stringData["password"], "hunter2")
+	}
+}
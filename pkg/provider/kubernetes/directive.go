@@ -0,0 +1,113 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import "fmt"
+
+// patchDirectiveKey is a map-level sentinel, not a real label/annotation key.
+// It is reserved the same way strategic-merge-patch reserves "$patch" on list
+// and map fields, and is stripped out of the map before it is applied.
+const patchDirectiveKey = "$patch"
+
+type patchDirective string
+
+const (
+	// patchDirectiveDelete drops every key of the local Secret missing from
+	// the map, equivalent to expressing sourceMergePolicyReplace inline. It
+	// is honored by mergeSourceMetadata only: keys already on the remote
+	// Secret are dropped only where the target merge policy drops them.
+	patchDirectiveDelete patchDirective = "delete"
+	// patchDirectiveRetain keeps every key of the local Secret missing from
+	// the map, equivalent to expressing sourceMergePolicyMerge inline. It
+	// does not change how the target merge policy treats the remote Secret.
+	patchDirectiveRetain patchDirective = "retain"
+)
+
+// applyMetadataDirectives applies desired onto base, honoring:
+//   - a `null` value for a key, which deletes that key from the result
+//     regardless of replace;
+//   - a `$patch: delete`/`$patch: retain` entry, which overrides replace for
+//     this map only.
+//
+// replace is the fallback behavior (from the enclosing Merge/Replace policy)
+// used when desired carries no `$patch` directive of its own.
+func applyMetadataDirectives(base map[string]string, desired map[string]*string, replace bool) (map[string]string, error) {
+	directive, values, err := splitPatchDirective(desired)
+	if err != nil {
+		return nil, err
+	}
+	switch directive {
+	case patchDirectiveDelete:
+		replace = true
+	case patchDirectiveRetain:
+		replace = false
+	}
+
+	result := make(map[string]string, len(base)+len(values))
+	if !replace {
+		for k, v := range base {
+			result[k] = v
+		}
+	}
+	for k, v := range values {
+		if v == nil {
+			delete(result, k)
+			continue
+		}
+		result[k] = *v
+	}
+	return result, nil
+}
+
+// deletedKeys returns the keys of m that carry an explicit `null` value,
+// i.e. the keys the user asked to delete from the target regardless of the
+// enclosing merge policy. The reserved "$patch" sentinel is never included.
+func deletedKeys(m map[string]*string) []string {
+	var deleted []string
+	for k, v := range m {
+		if k == patchDirectiveKey || v != nil {
+			continue
+		}
+		deleted = append(deleted, k)
+	}
+	return deleted
+}
+
+// splitPatchDirective pulls the reserved "$patch" sentinel, if any, out of m
+// and returns it alongside the remaining, real entries. An unrecognized
+// directive value is rejected outright rather than silently ignored.
+func splitPatchDirective(m map[string]*string) (patchDirective, map[string]*string, error) {
+	if m == nil {
+		return "", nil, nil
+	}
+	values := make(map[string]*string, len(m))
+	var directive patchDirective
+	for k, v := range m {
+		if k != patchDirectiveKey {
+			values[k] = v
+			continue
+		}
+		if v == nil {
+			return "", nil, fmt.Errorf("%s: %q must not be null", metadataAPIVersion, patchDirectiveKey)
+		}
+		switch patchDirective(*v) {
+		case patchDirectiveDelete, patchDirectiveRetain:
+			directive = patchDirective(*v)
+		default:
+			return "", nil, fmt.Errorf("%s: unknown %s directive %q, expected %q or %q", metadataAPIVersion, patchDirectiveKey, *v, patchDirectiveDelete, patchDirectiveRetain)
+		}
+	}
+	return directive, values, nil
+}
@@ -0,0 +1,111 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestApplyMetadataDirectives(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    map[string]string
+		desired map[string]*string
+		replace bool
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "merge keeps base and overlays desired",
+			base:    map[string]string{"a": "1", "b": "2"},
+			desired: map[string]*string{"b": strPtr("20"), "c": strPtr("3")},
+			replace: false,
+			want:    map[string]string{"a": "1", "b": "20", "c": "3"},
+		},
+		{
+			name:    "null deletes a key under merge",
+			base:    map[string]string{"a": "1", "b": "2"},
+			desired: map[string]*string{"b": nil},
+			replace: false,
+			want:    map[string]string{"a": "1"},
+		},
+		{
+			name:    "replace drops keys missing from desired",
+			base:    map[string]string{"a": "1", "b": "2"},
+			desired: map[string]*string{"c": strPtr("3")},
+			replace: true,
+			want:    map[string]string{"c": "3"},
+		},
+		{
+			name:    "$patch retain overrides replace",
+			base:    map[string]string{"a": "1", "b": "2"},
+			desired: map[string]*string{patchDirectiveKey: strPtr("retain"), "c": strPtr("3")},
+			replace: true,
+			want:    map[string]string{"a": "1", "b": "2", "c": "3"},
+		},
+		{
+			name:    "$patch delete overrides merge",
+			base:    map[string]string{"a": "1", "b": "2"},
+			desired: map[string]*string{patchDirectiveKey: strPtr("delete"), "c": strPtr("3")},
+			replace: false,
+			want:    map[string]string{"c": "3"},
+		},
+		{
+			name:    "unknown $patch value is rejected",
+			base:    map[string]string{"a": "1"},
+			desired: map[string]*string{patchDirectiveKey: strPtr("bogus")},
+			wantErr: true,
+		},
+		{
+			name:    "null $patch value is rejected",
+			base:    map[string]string{"a": "1"},
+			desired: map[string]*string{patchDirectiveKey: nil},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyMetadataDirectives(tt.base, tt.desired, tt.replace)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("applyMetadataDirectives() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyMetadataDirectives() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyMetadataDirectives() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeletedKeys(t *testing.T) {
+	m := map[string]*string{
+		"a":               strPtr("1"),
+		"b":               nil,
+		patchDirectiveKey: strPtr("delete"),
+	}
+	got := deletedKeys(m)
+	if !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("deletedKeys() = %v, want [\"b\"]", got)
+	}
+}
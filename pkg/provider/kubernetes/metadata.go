@@ -20,14 +20,17 @@ import (
 	v1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/yaml"
-)
 
-const (
-	metadataAPIVersion = "kubernetes.external-secrets.io/v1alpha1"
-	metadataKind       = "PushSecretMetadata"
+	"github.com/newtondev/external-secrets/pkg/provider/util/pushmeta"
 )
 
+const metadataKind = "PushSecretMetadata"
+
+// metadataAPIVersion is the version directive.go's error messages refer
+// users to: the $patch directives it documents only exist from v1beta1
+// onward.
+const metadataAPIVersion = metadataAPIVersionV1Beta1
+
 type PushSecretMetadata struct {
 	metav1.TypeMeta
 	Spec PushSecretMetadataSpec `json:"spec,omitempty"`
@@ -36,10 +39,37 @@ type PushSecretMetadataSpec struct {
 	TargetMergePolicy targetMergePolicy `json:"targetMergePolicy,omitempty"`
 	SourceMergePolicy sourceMergePolicy `json:"sourceMergePolicy,omitempty"`
 
-	Labels      map[string]string `json:"labels,omitempty"`
-	Annotations map[string]string `json:"annotations,omitempty"`
+	// ApplyMode selects how the target Secret is written. Defaults to Patch.
+	ApplyMode applyMode `json:"applyMode,omitempty"`
+	// FieldManager is the field manager used when ApplyMode is ServerSideApply.
+	// Defaults to "external-secrets".
+	FieldManager string `json:"fieldManager,omitempty"`
+	// Force takes ownership of fields owned by another manager when ApplyMode
+	// is ServerSideApply and a conflict is detected. Defaults to false.
+	Force bool `json:"force,omitempty"`
+
+	// Labels and Annotations accept the usual string values, plus two
+	// strategic-merge-patch style directives: a JSON `null` value deletes
+	// that key from the target (except under the Ignore target merge
+	// policy), and a `$patch` entry at the map level ("delete" or "retain")
+	// controls whether keys of the local Secret missing from this map are
+	// dropped or kept. The `$patch` entry does not decide which keys already
+	// on the remote Secret are dropped; the target merge policy alone does.
+	// See directive.go.
+	Labels      map[string]*string `json:"labels,omitempty"`
+	Annotations map[string]*string `json:"annotations,omitempty"`
 }
 
+type applyMode string
+
+const (
+	applyModeUpdate          applyMode = "Update"
+	applyModePatch           applyMode = "Patch"
+	applyModeServerSideApply applyMode = "ServerSideApply"
+
+	defaultFieldManager = "external-secrets"
+)
+
 type targetMergePolicy string
 
 const (
@@ -55,25 +85,11 @@ const (
 	sourceMergePolicyReplace sourceMergePolicy = "Replace"
 )
 
+// parseMetadataParameters decodes data against metadataScheme and returns it
+// upgraded to v1beta1, regardless of which registered apiVersion the caller
+// actually used.
 func parseMetadataParameters(data *apiextensionsv1.JSON) (*PushSecretMetadata, error) {
-	if data == nil {
-		return nil, nil
-	}
-	var metadata PushSecretMetadata
-	err := yaml.Unmarshal(data.Raw, &metadata, yaml.DisallowUnknownFields)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse %s %s: %w", metadataAPIVersion, metadataKind, err)
-	}
-
-	if metadata.APIVersion != metadataAPIVersion {
-		return nil, fmt.Errorf("unexpected apiVersion %q, expected %q", metadata.APIVersion, metadataAPIVersion)
-	}
-
-	if metadata.Kind != metadataKind {
-		return nil, fmt.Errorf("unexpected kind %q, expected %q", metadata.Kind, metadataKind)
-	}
-
-	return &metadata, nil
+	return pushmeta.Parse[PushSecretMetadata](metadataScheme, metadataAPIVersionV1Beta1, data)
 }
 
 // Takes the local secret metadata and merges it with the push metadata.
@@ -85,42 +101,55 @@ func mergeSourceMetadata(localSecret *v1.Secret, pushMeta *PushSecretMetadata) (
 	if pushMeta == nil {
 		return labels, annotations, nil
 	}
-	if labels == nil {
-		labels = make(map[string]string)
-	}
-	if annotations == nil {
-		annotations = make(map[string]string)
-	}
 
 	switch pushMeta.Spec.SourceMergePolicy {
 	case "", sourceMergePolicyMerge:
-		for k, v := range pushMeta.Spec.Labels {
-			labels[k] = v
-		}
-		for k, v := range pushMeta.Spec.Annotations {
-			annotations[k] = v
-		}
 	case sourceMergePolicyReplace:
-		labels = pushMeta.Spec.Labels
-		annotations = pushMeta.Spec.Annotations
 	default:
 		return nil, nil, fmt.Errorf("unexpected source merge policy %q", pushMeta.Spec.SourceMergePolicy)
 	}
+	replace := pushMeta.Spec.SourceMergePolicy == sourceMergePolicyReplace
+
+	labels, err := applyMetadataDirectives(labels, pushMeta.Spec.Labels, replace)
+	if err != nil {
+		return nil, nil, fmt.Errorf("labels: %w", err)
+	}
+	annotations, err = applyMetadataDirectives(annotations, pushMeta.Spec.Annotations, replace)
+	if err != nil {
+		return nil, nil, fmt.Errorf("annotations: %w", err)
+	}
 	return labels, annotations, nil
 }
 
+// cloneStringMap returns a shallow copy of m, never aliasing m itself, so a
+// caller can delete/overwrite keys in the result without mutating m as an
+// undocumented side effect. A nil m clones to an empty, non-nil map.
+func cloneStringMap(m map[string]string) map[string]string {
+	clone := make(map[string]string, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
+
 // Takes the remote secret metadata and merges it with the source metadata.
 // The source metadata may replace the existing labels/annotations
 // or merge into it depending on policy.
+//
+// Under both the Merge and the Replace policy, any key the user explicitly
+// set to `null` in pushMeta.Spec.Labels/Annotations (see directive.go) is
+// removed from the target; under Ignore the remote metadata is returned
+// unchanged and no key is removed. Under the default Merge policy a null value would otherwise be a
+// no-op, since sourceLabels/sourceAnnotations (mergeSourceMetadata's output)
+// never carries deleted keys in the first place, so there would be nothing
+// here to tell "never set" and "explicitly remove" apart.
 func mergeTargetMetadata(remoteSecret *v1.Secret, pushMeta *PushSecretMetadata, sourceLabels, sourceAnnotations map[string]string) (map[string]string, map[string]string, error) {
-	labels := remoteSecret.ObjectMeta.Labels
-	annotations := remoteSecret.ObjectMeta.Annotations
-	if labels == nil {
-		labels = make(map[string]string)
-	}
-	if annotations == nil {
-		annotations = make(map[string]string)
-	}
+	// Cloned rather than aliased: remoteSecret.ObjectMeta.Labels/Annotations and
+	// sourceLabels/sourceAnnotations (mergeSourceMetadata's output, also handed
+	// out separately as the SSA-owned fields) are both maps callers still hold
+	// onto after this call returns, and must see in their original state.
+	labels := cloneStringMap(remoteSecret.ObjectMeta.Labels)
+	annotations := cloneStringMap(remoteSecret.ObjectMeta.Annotations)
 	var targetMergePolicy targetMergePolicy
 	if pushMeta != nil {
 		targetMergePolicy = pushMeta.Spec.TargetMergePolicy
@@ -135,14 +164,24 @@ func mergeTargetMetadata(remoteSecret *v1.Secret, pushMeta *PushSecretMetadata,
 			annotations[k] = v
 		}
 	case targetMergePolicyReplace:
-		labels = sourceLabels
-		annotations = sourceAnnotations
+		labels = cloneStringMap(sourceLabels)
+		annotations = cloneStringMap(sourceAnnotations)
 	case targetMergePolicyIgnore:
 		// leave the target metadata as is
 		// this is useful when we only want to push data
 		// and the user does not want to touch the metadata
+		return labels, annotations, nil
 	default:
 		return nil, nil, fmt.Errorf("unexpected target merge policy %q", targetMergePolicy)
 	}
+
+	if pushMeta != nil {
+		for _, k := range deletedKeys(pushMeta.Spec.Labels) {
+			delete(labels, k)
+		}
+		for _, k := range deletedKeys(pushMeta.Spec.Annotations) {
+			delete(annotations, k)
+		}
+	}
 	return labels, annotations, nil
-}
\ No newline at end of file
+}
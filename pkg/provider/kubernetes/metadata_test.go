@@ -0,0 +1,92 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestMergeSourceThenTargetMetadataDeletesNullLabel exercises the full
+// mergeSourceMetadata -> mergeTargetMetadata pipeline under the default
+// (Merge) target policy. It is a regression test for a null-valued label
+// directive being dropped from sourceLabels by mergeSourceMetadata but never
+// actually removed from the target: mergeTargetMetadata must consult the
+// directive itself, not just the already-collapsed source labels, or a
+// `null` value is a no-op against the remote Secret.
+func TestMergeSourceThenTargetMetadataDeletesNullLabel(t *testing.T) {
+	localSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"team": "payments", "app": "checkout"},
+		},
+	}
+	remoteSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"team": "payments", "env": "prod"},
+		},
+	}
+	pushMeta := &PushSecretMetadata{
+		Spec: PushSecretMetadataSpec{
+			Labels: map[string]*string{"team": nil},
+		},
+	}
+
+	sourceLabels, sourceAnnotations, err := mergeSourceMetadata(localSecret, pushMeta)
+	if err != nil {
+		t.Fatalf("mergeSourceMetadata() error = %v", err)
+	}
+	if _, ok := sourceLabels["team"]; ok {
+		t.Fatalf("sourceLabels still has 'team': %v", sourceLabels)
+	}
+
+	targetLabels, _, err := mergeTargetMetadata(remoteSecret, pushMeta, sourceLabels, sourceAnnotations)
+	if err != nil {
+		t.Fatalf("mergeTargetMetadata() error = %v", err)
+	}
+
+	if _, ok := targetLabels["team"]; ok {
+		t.Errorf("target still carries the deleted label 'team': %v", targetLabels)
+	}
+	want := map[string]string{"env": "prod", "app": "checkout"}
+	if !reflect.DeepEqual(targetLabels, want) {
+		t.Errorf("targetLabels = %v, want %v", targetLabels, want)
+	}
+}
+
+func TestMergeTargetMetadataIgnorePolicyLeavesRemoteUntouched(t *testing.T) {
+	remoteSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"team": "payments"},
+		},
+	}
+	pushMeta := &PushSecretMetadata{
+		Spec: PushSecretMetadataSpec{
+			TargetMergePolicy: targetMergePolicyIgnore,
+			Labels:            map[string]*string{"team": nil},
+		},
+	}
+
+	targetLabels, _, err := mergeTargetMetadata(remoteSecret, pushMeta, map[string]string{}, map[string]string{})
+	if err != nil {
+		t.Fatalf("mergeTargetMetadata() error = %v", err)
+	}
+	want := map[string]string{"team": "payments"}
+	if !reflect.DeepEqual(targetLabels, want) {
+		t.Errorf("targetLabels = %v, want %v (Ignore must not apply deletions either)", targetLabels, want)
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// buildTargetPatch computes the patch that moves remoteSecret to the desired
+// labels/annotations/data, honoring targetMergePolicy:
+//   - Merge, Replace: a two-way strategic merge patch against labels/
+//     annotations as already computed by mergeTargetMetadata. No extra
+//     handling is needed to delete keys missing from the desired state:
+//     labels/annotations have no patch-merge-key, so
+//     strategicpatch.CreateTwoWayMergePatch nulls out any key present on the
+//     remote and absent from the desired state on its own, regardless of
+//     policy. The Merge/Replace distinction lives entirely upstream, in what
+//     mergeTargetMetadata computed the desired state to be.
+//   - Ignore: the modified object keeps the remote's labels/annotations
+//     unchanged, so the resulting patch changes only data (plus the
+//     resourceVersion lock below).
+//
+// The remote Secret's resourceVersion is embedded in the patch so that
+// submitting it via client.Patch fails with a 409 Conflict if the remote has
+// changed since it was fetched, giving us optimistic locking for free.
+func buildTargetPatch(remoteSecret *v1.Secret, policy targetMergePolicy, labels, annotations map[string]string, data map[string][]byte) (client.Patch, error) {
+	original, err := json.Marshal(remoteSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal remote secret: %w", err)
+	}
+
+	modified := remoteSecret.DeepCopy()
+	modified.Data = data
+
+	switch policy {
+	case targetMergePolicyIgnore:
+		// leave ObjectMeta as-is: only data/stringData should end up in the patch.
+	case "", targetMergePolicyMerge, targetMergePolicyReplace:
+		modified.ObjectMeta.Labels = labels
+		modified.ObjectMeta.Annotations = annotations
+	default:
+		return nil, fmt.Errorf("unexpected target merge policy %q", policy)
+	}
+
+	modifiedBytes, err := json.Marshal(modified)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal desired secret: %w", err)
+	}
+
+	patchBytes, err := strategicpatch.CreateTwoWayMergePatch(original, modifiedBytes, v1.Secret{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create strategic merge patch: %w", err)
+	}
+
+	patchBytes, err = withResourceVersionLock(patchBytes, remoteSecret.ResourceVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.RawPatch(types.StrategicMergePatchType, patchBytes), nil
+}
+
+// withResourceVersionLock rewrites patchBytes so the patch also pins
+// metadata.resourceVersion to expected. Kubernetes honors resourceVersion on
+// incoming patches the same way it does on updates, so a stale patch is
+// rejected with a 409 instead of being applied blind.
+func withResourceVersionLock(patchBytes []byte, expected string) ([]byte, error) {
+	var patch map[string]interface{}
+	if err := json.Unmarshal(patchBytes, &patch); err != nil {
+		return nil, fmt.Errorf("failed to decode patch for optimistic lock: %w", err)
+	}
+	metadata, _ := patch["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["resourceVersion"] = expected
+	patch["metadata"] = metadata
+
+	return json.Marshal(patch)
+}
+
+// patchRemoteSecret fetches the current state of key, computes the patch via
+// build, and submits it. On a resourceVersion conflict it re-reads the remote
+// Secret and retries with backoff, so a concurrent writer never gets
+// silently clobbered.
+func patchRemoteSecret(ctx context.Context, c client.Client, key client.ObjectKey, build func(remoteSecret *v1.Secret) (client.Patch, error)) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		remoteSecret := &v1.Secret{}
+		if err := c.Get(ctx, key, remoteSecret); err != nil {
+			return fmt.Errorf("failed to get remote secret %s: %w", key, err)
+		}
+
+		patch, err := build(remoteSecret)
+		if err != nil {
+			return err
+		}
+
+		if err := c.Patch(ctx, remoteSecret, patch); err != nil {
+			return fmt.Errorf("failed to patch remote secret %s: %w", key, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,247 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newRemoteTestSecret() *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "target",
+			Namespace:       "default",
+			ResourceVersion: "42",
+			Labels:          map[string]string{"team": "payments", "env": "prod"},
+			Annotations:     map[string]string{"keep": "me"},
+		},
+		Data: map[string][]byte{"password": []byte("hunter2")},
+	}
+}
+
+// applyPatchForTest round-trips patch through strategicpatch.StrategicMergePatch
+// the same way the API server would, so assertions exercise the actual patch
+// semantics instead of the raw JSON shape buildTargetPatch happened to emit.
+func applyPatchForTest(t *testing.T, original *v1.Secret, patch client.Patch) *v1.Secret {
+	t.Helper()
+
+	originalBytes, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("failed to marshal original secret: %v", err)
+	}
+	patchBytes, err := patch.Data(original)
+	if err != nil {
+		t.Fatalf("failed to get patch data: %v", err)
+	}
+	patchedBytes, err := strategicpatch.StrategicMergePatch(originalBytes, patchBytes, &v1.Secret{})
+	if err != nil {
+		t.Fatalf("failed to apply patch: %v", err)
+	}
+
+	var patched v1.Secret
+	if err := json.Unmarshal(patchedBytes, &patched); err != nil {
+		t.Fatalf("failed to unmarshal patched secret: %v", err)
+	}
+	return &patched
+}
+
+func TestBuildTargetPatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     targetMergePolicy
+		labels     map[string]string
+		wantLabels map[string]string
+	}{
+		{
+			// labels is the desired state as mergeTargetMetadata computes it
+			// under Merge: the remote's labels plus the new "owner" key.
+			name:       "merge keeps untouched keys and adds new ones",
+			policy:     targetMergePolicyMerge,
+			labels:     map[string]string{"team": "payments", "env": "prod", "owner": "sre"},
+			wantLabels: map[string]string{"team": "payments", "env": "prod", "owner": "sre"},
+		},
+		{
+			// Regression test: a key missing from the desired state must be
+			// removed from the remote even under the default Merge policy,
+			// not just under Replace.
+			name:       "merge removes a key the desired state no longer carries",
+			policy:     targetMergePolicyMerge,
+			labels:     map[string]string{"env": "prod"},
+			wantLabels: map[string]string{"env": "prod"},
+		},
+		{
+			name:       "replace drops every key not in the desired state",
+			policy:     targetMergePolicyReplace,
+			labels:     map[string]string{"owner": "sre"},
+			wantLabels: map[string]string{"owner": "sre"},
+		},
+		{
+			name:       "ignore leaves labels untouched",
+			policy:     targetMergePolicyIgnore,
+			labels:     map[string]string{"owner": "sre"},
+			wantLabels: map[string]string{"team": "payments", "env": "prod"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote := newRemoteTestSecret()
+
+			patch, err := buildTargetPatch(remote, tt.policy, tt.labels, remote.ObjectMeta.Annotations, remote.Data)
+			if err != nil {
+				t.Fatalf("buildTargetPatch() error = %v", err)
+			}
+			if patch.Type() != types.StrategicMergePatchType {
+				t.Fatalf("patch type = %v, want %v", patch.Type(), types.StrategicMergePatchType)
+			}
+
+			patched := applyPatchForTest(t, remote, patch)
+			if !reflect.DeepEqual(patched.ObjectMeta.Labels, tt.wantLabels) {
+				t.Errorf("labels = %v, want %v", patched.ObjectMeta.Labels, tt.wantLabels)
+			}
+		})
+	}
+}
+
+func TestBuildTargetPatchEmbedsResourceVersionLock(t *testing.T) {
+	remote := newRemoteTestSecret()
+
+	patch, err := buildTargetPatch(remote, targetMergePolicyMerge, remote.ObjectMeta.Labels, remote.ObjectMeta.Annotations, remote.Data)
+	if err != nil {
+		t.Fatalf("buildTargetPatch() error = %v", err)
+	}
+
+	patchBytes, err := patch.Data(remote)
+	if err != nil {
+		t.Fatalf("failed to get patch data: %v", err)
+	}
+	var raw struct {
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(patchBytes, &raw); err != nil {
+		t.Fatalf("failed to decode patch: %v", err)
+	}
+	if raw.Metadata.ResourceVersion != remote.ResourceVersion {
+		t.Errorf("patch metadata.resourceVersion = %q, want %q", raw.Metadata.ResourceVersion, remote.ResourceVersion)
+	}
+
+	patched := applyPatchForTest(t, remote, patch)
+	if patched.ResourceVersion != remote.ResourceVersion {
+		t.Errorf("resourceVersion = %q, want %q", patched.ResourceVersion, remote.ResourceVersion)
+	}
+}
+
+// conflictOnceClient is a minimal client.Client double around a single
+// in-memory Secret. Its first Patch call simulates a concurrent writer
+// landing between our Get and our Patch: it applies concurrentWrite to the
+// stored Secret and returns a 409, so the caller's conflict-retry re-Gets the
+// now-changed Secret. Every later Patch call applies via the same strategic
+// merge patch machinery the real API server uses, so assertions exercise the
+// patch actually produced on retry.
+type conflictOnceClient struct {
+	client.Client
+	remote          *v1.Secret
+	conflicted      bool
+	concurrentWrite func(*v1.Secret)
+}
+
+func (f *conflictOnceClient) Get(_ context.Context, _ client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	secret, ok := obj.(*v1.Secret)
+	if !ok {
+		return fmt.Errorf("unexpected object type %T", obj)
+	}
+	*secret = *f.remote.DeepCopy()
+	return nil
+}
+
+func (f *conflictOnceClient) Patch(_ context.Context, obj client.Object, patch client.Patch, _ ...client.PatchOption) error {
+	if !f.conflicted {
+		f.conflicted = true
+		if f.concurrentWrite != nil {
+			f.concurrentWrite(f.remote)
+		}
+		return apierrors.NewConflict(schema.GroupResource{Resource: "secrets"}, f.remote.Name, fmt.Errorf("stale resourceVersion"))
+	}
+
+	originalBytes, err := json.Marshal(f.remote)
+	if err != nil {
+		return err
+	}
+	patchBytes, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	patchedBytes, err := strategicpatch.StrategicMergePatch(originalBytes, patchBytes, &v1.Secret{})
+	if err != nil {
+		return err
+	}
+	var patched v1.Secret
+	if err := json.Unmarshal(patchedBytes, &patched); err != nil {
+		return err
+	}
+	f.remote = &patched
+	return nil
+}
+
+// TestPatchRemoteSecretRecomputesAgainstFreshRemoteOnConflict is a regression
+// test: on a 409 conflict, the retry must recompute the desired labels from
+// the freshly re-fetched remote Secret, not replay the patch it built from
+// the stale one - otherwise a label a concurrent writer added in between gets
+// diffed away as "missing from desired" and silently deleted.
+func TestPatchRemoteSecretRecomputesAgainstFreshRemoteOnConflict(t *testing.T) {
+	remote := newRemoteTestSecret()
+	fc := &conflictOnceClient{
+		remote: remote,
+		concurrentWrite: func(s *v1.Secret) {
+			s.ObjectMeta.Labels["added-concurrently"] = "yes"
+			s.ObjectMeta.ResourceVersion = "43"
+		},
+	}
+
+	sourceLabels := map[string]string{"owner": "sre"}
+	pushMeta := &PushSecretMetadata{}
+
+	err := patchRemoteSecret(context.Background(), fc, client.ObjectKey{Name: "target", Namespace: "default"}, func(remoteSecret *v1.Secret) (client.Patch, error) {
+		targetLabels, targetAnnotations, err := mergeTargetMetadata(remoteSecret, pushMeta, sourceLabels, nil)
+		if err != nil {
+			return nil, err
+		}
+		return buildTargetPatch(remoteSecret, targetMergePolicyMerge, targetLabels, targetAnnotations, remoteSecret.Data)
+	})
+	if err != nil {
+		t.Fatalf("patchRemoteSecret() error = %v", err)
+	}
+
+	if _, ok := fc.remote.ObjectMeta.Labels["added-concurrently"]; !ok {
+		t.Errorf("label added by the concurrent writer was clobbered by the retried patch: %v", fc.remote.ObjectMeta.Labels)
+	}
+	if fc.remote.ObjectMeta.Labels["owner"] != "sre" {
+		t.Errorf("labels = %v, want owner=sre from our own retried write", fc.remote.ObjectMeta.Labels)
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/newtondev/external-secrets/pkg/provider/util/pushmeta"
+)
+
+const (
+	metadataGroup              = "kubernetes.external-secrets.io"
+	metadataAPIVersionV1Alpha1 = metadataGroup + "/v1alpha1"
+	metadataAPIVersionV1Beta1  = metadataGroup + "/v1beta1"
+)
+
+// metadataScheme knows how to decode both versions of PushSecretMetadata
+// this provider has ever accepted and upgrade them to v1beta1, which is the
+// shape the rest of the package works with.
+var metadataScheme = pushmeta.New(metadataKind)
+
+func init() {
+	pushmeta.Register[pushSecretMetadataV1Alpha1](metadataScheme, metadataAPIVersionV1Alpha1)
+	pushmeta.Register[PushSecretMetadata](metadataScheme, metadataAPIVersionV1Beta1)
+	pushmeta.RegisterConversion(metadataScheme, metadataAPIVersionV1Alpha1, metadataAPIVersionV1Beta1, convertV1Alpha1ToV1Beta1)
+}
+
+// pushSecretMetadataV1Alpha1 is the original shape of PushSecretMetadata:
+// plain string labels/annotations with no $patch directives, and no
+// applyMode/fieldManager/force. It is kept only so manifests written before
+// v1beta1 keep parsing; it must not gain new fields - add those to
+// PushSecretMetadataSpec (v1beta1) instead.
+type pushSecretMetadataV1Alpha1 struct {
+	metav1.TypeMeta
+	Spec pushSecretMetadataV1Alpha1Spec `json:"spec,omitempty"`
+}
+
+type pushSecretMetadataV1Alpha1Spec struct {
+	TargetMergePolicy targetMergePolicy `json:"targetMergePolicy,omitempty"`
+	SourceMergePolicy sourceMergePolicy `json:"sourceMergePolicy,omitempty"`
+
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// convertV1Alpha1ToV1Beta1 upgrades a v1alpha1 PushSecretMetadata to v1beta1.
+// v1alpha1 never had null-valued labels/annotations, so every value is
+// simply wrapped; the v1beta1-only fields (ApplyMode, FieldManager, Force)
+// come back zero-valued, so a v1alpha1 document gets their defaults
+// (Patch, "external-secrets", false), not the legacy Update apply mode.
+func convertV1Alpha1ToV1Beta1(in *pushSecretMetadataV1Alpha1) (*PushSecretMetadata, error) {
+	return &PushSecretMetadata{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: metadataAPIVersionV1Beta1,
+			Kind:       metadataKind,
+		},
+		Spec: PushSecretMetadataSpec{
+			TargetMergePolicy: in.Spec.TargetMergePolicy,
+			SourceMergePolicy: in.Spec.SourceMergePolicy,
+			Labels:            toNullableMap(in.Spec.Labels),
+			Annotations:       toNullableMap(in.Spec.Annotations),
+		},
+	}, nil
+}
+
+func toNullableMap(m map[string]string) map[string]*string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]*string, len(m))
+	for k, v := range m {
+		v := v
+		out[k] = &v
+	}
+	return out
+}
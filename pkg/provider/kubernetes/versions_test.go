@@ -0,0 +1,88 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestConvertV1Alpha1ToV1Beta1(t *testing.T) {
+	in := &pushSecretMetadataV1Alpha1{
+		Spec: pushSecretMetadataV1Alpha1Spec{
+			TargetMergePolicy: targetMergePolicyReplace,
+			SourceMergePolicy: sourceMergePolicyMerge,
+			Labels:            map[string]string{"team": "payments"},
+			Annotations:       map[string]string{"owner": "sre"},
+		},
+	}
+
+	out, err := convertV1Alpha1ToV1Beta1(in)
+	if err != nil {
+		t.Fatalf("convertV1Alpha1ToV1Beta1() error = %v", err)
+	}
+
+	if out.APIVersion != metadataAPIVersionV1Beta1 || out.Kind != metadataKind {
+		t.Errorf("typeMeta = %+v, want apiVersion %q kind %q", out.TypeMeta, metadataAPIVersionV1Beta1, metadataKind)
+	}
+	if out.Spec.TargetMergePolicy != targetMergePolicyReplace {
+		t.Errorf("targetMergePolicy = %q, want %q", out.Spec.TargetMergePolicy, targetMergePolicyReplace)
+	}
+	if out.Spec.Labels["team"] == nil || *out.Spec.Labels["team"] != "payments" {
+		t.Errorf("labels[team] = %v, want \"payments\"", out.Spec.Labels["team"])
+	}
+	if out.Spec.ApplyMode != "" || out.Spec.FieldManager != "" || out.Spec.Force {
+		t.Errorf("v1beta1-only fields should be zero-valued after conversion, got %+v", out.Spec)
+	}
+}
+
+func TestParseMetadataParametersAcceptsBothVersions(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{
+			name: "v1alpha1",
+			raw:  `{"apiVersion":"` + metadataAPIVersionV1Alpha1 + `","kind":"PushSecretMetadata","spec":{"labels":{"team":"payments"}}}`,
+		},
+		{
+			name: "v1beta1",
+			raw:  `{"apiVersion":"` + metadataAPIVersionV1Beta1 + `","kind":"PushSecretMetadata","spec":{"labels":{"team":"payments"},"applyMode":"ServerSideApply"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMetadataParameters(&apiextensionsv1.JSON{Raw: []byte(tt.raw)})
+			if err != nil {
+				t.Fatalf("parseMetadataParameters() error = %v", err)
+			}
+			if got.APIVersion != metadataAPIVersionV1Beta1 {
+				t.Errorf("APIVersion = %q, want %q", got.APIVersion, metadataAPIVersionV1Beta1)
+			}
+			if got.Spec.Labels["team"] == nil || *got.Spec.Labels["team"] != "payments" {
+				t.Errorf("labels[team] = %v, want \"payments\"", got.Spec.Labels["team"])
+			}
+		})
+	}
+}
+
+func TestParseMetadataParametersRejectsUnknownVersion(t *testing.T) {
+	raw := `{"apiVersion":"kubernetes.external-secrets.io/v2","kind":"PushSecretMetadata"}`
+	if _, err := parseMetadataParameters(&apiextensionsv1.JSON{Raw: []byte(raw)}); err == nil {
+		t.Fatal("parseMetadataParameters() error = nil, want an error for an unregistered apiVersion")
+	}
+}
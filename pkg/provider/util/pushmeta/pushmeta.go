@@ -0,0 +1,132 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pushmeta provides a small versioned scheme for the inline
+// PushSecretMetadata documents that providers accept as PushSecret
+// `metadata.spec.target.pushSecretMetadata` parameters.
+//
+// Every provider used to hard-code a single apiVersion and hand-roll its own
+// parse function; that made it impossible to evolve a provider's metadata
+// shape without breaking manifests already using it. A Scheme lets a
+// provider register one Go type per apiVersion plus the conversion functions
+// between them, and exposes a single Parse that decodes whichever version
+// was sent and upgrades it to whichever version the provider wants to work
+// against internally.
+package pushmeta
+
+import (
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Scheme is a per-kind registry of versioned types and the conversions
+// between them. Construct one with New per provider group/kind pair.
+type Scheme struct {
+	kind     string
+	versions map[string]versionEntry
+}
+
+type versionEntry struct {
+	newObj    func() any
+	convertTo map[string]func(any) (any, error)
+}
+
+// New returns an empty Scheme for the given kind, e.g. "PushSecretMetadata".
+func New(kind string) *Scheme {
+	return &Scheme{kind: kind, versions: map[string]versionEntry{}}
+}
+
+// Register associates apiVersion (e.g.
+// "kubernetes.external-secrets.io/v1beta1") with the Go type T, so Parse can
+// decode documents declaring that apiVersion into a *T.
+func Register[T any](s *Scheme, apiVersion string) {
+	s.versions[apiVersion] = versionEntry{
+		newObj:    func() any { return new(T) },
+		convertTo: map[string]func(any) (any, error){},
+	}
+}
+
+// RegisterConversion registers how to upgrade a decoded object at fromVersion
+// (Go type From) into toVersion (Go type To). Both versions must already be
+// Register'd. Conversions are looked up by the exact (fromVersion, toVersion)
+// pair; there is no multi-hop chaining, so register a direct conversion for
+// every version a provider still needs to read.
+func RegisterConversion[From, To any](s *Scheme, fromVersion, toVersion string, fn func(*From) (*To, error)) {
+	entry := s.versions[fromVersion]
+	entry.convertTo[toVersion] = func(obj any) (any, error) {
+		return fn(obj.(*From))
+	}
+	s.versions[fromVersion] = entry
+}
+
+// Parse decodes data using the type registered under its declared
+// apiVersion, rejecting unknown fields and unknown versions, then converts
+// the result to targetVersion if it isn't already there. T must be the Go
+// type Register'd under targetVersion.
+func Parse[T any](s *Scheme, targetVersion string, data *apiextensionsv1.JSON) (*T, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	var tm metav1.TypeMeta
+	if err := yaml.Unmarshal(data.Raw, &tm); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.kind, err)
+	}
+	if tm.Kind != s.kind {
+		return nil, fmt.Errorf("unexpected kind %q, expected %q", tm.Kind, s.kind)
+	}
+
+	entry, ok := s.versions[tm.APIVersion]
+	if !ok {
+		return nil, fmt.Errorf("unknown apiVersion %q for %s, known versions: %v", tm.APIVersion, s.kind, s.knownVersions())
+	}
+
+	obj := entry.newObj()
+	if err := yaml.Unmarshal(data.Raw, obj, yaml.DisallowUnknownFields); err != nil {
+		return nil, fmt.Errorf("failed to parse %s %s: %w", tm.APIVersion, s.kind, err)
+	}
+
+	if tm.APIVersion == targetVersion {
+		t, ok := obj.(*T)
+		if !ok {
+			return nil, fmt.Errorf("internal error: %s %s is not registered as the requested type", s.kind, tm.APIVersion)
+		}
+		return t, nil
+	}
+
+	convert, ok := entry.convertTo[targetVersion]
+	if !ok {
+		return nil, fmt.Errorf("no conversion registered from %q to %q for %s", tm.APIVersion, targetVersion, s.kind)
+	}
+	converted, err := convert(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s from %q to %q: %w", s.kind, tm.APIVersion, targetVersion, err)
+	}
+	t, ok := converted.(*T)
+	if !ok {
+		return nil, fmt.Errorf("internal error: conversion of %s to %q produced an unexpected type", s.kind, targetVersion)
+	}
+	return t, nil
+}
+
+func (s *Scheme) knownVersions() []string {
+	out := make([]string, 0, len(s.versions))
+	for v := range s.versions {
+		out = append(out, v)
+	}
+	return out
+}
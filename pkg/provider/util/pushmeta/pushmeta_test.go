@@ -0,0 +1,108 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pushmeta
+
+import (
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+type fooV1 struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Value      string `json:"value"`
+}
+
+type fooV2 struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Value      string `json:"value"`
+	Extra      string `json:"extra"`
+}
+
+func TestParseConvertsToTargetVersion(t *testing.T) {
+	s := New("Foo")
+	Register[fooV1](s, "example.com/v1")
+	Register[fooV2](s, "example.com/v2")
+	RegisterConversion(s, "example.com/v1", "example.com/v2", func(in *fooV1) (*fooV2, error) {
+		return &fooV2{APIVersion: "example.com/v2", Kind: "Foo", Value: in.Value, Extra: "default"}, nil
+	})
+
+	raw := `{"apiVersion":"example.com/v1","kind":"Foo","value":"hello"}`
+	got, err := Parse[fooV2](s, "example.com/v2", &apiextensionsv1.JSON{Raw: []byte(raw)})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got.Value != "hello" || got.Extra != "default" {
+		t.Errorf("Parse() = %+v, want Value=hello Extra=default", got)
+	}
+}
+
+func TestParseReturnsNativeVersionWithoutConversion(t *testing.T) {
+	s := New("Foo")
+	Register[fooV2](s, "example.com/v2")
+
+	raw := `{"apiVersion":"example.com/v2","kind":"Foo","value":"hi","extra":"x"}`
+	got, err := Parse[fooV2](s, "example.com/v2", &apiextensionsv1.JSON{Raw: []byte(raw)})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got.Value != "hi" || got.Extra != "x" {
+		t.Errorf("Parse() = %+v", got)
+	}
+}
+
+func TestParseRejectsUnknownVersion(t *testing.T) {
+	s := New("Foo")
+	Register[fooV2](s, "example.com/v2")
+
+	raw := `{"apiVersion":"example.com/v3","kind":"Foo"}`
+	if _, err := Parse[fooV2](s, "example.com/v2", &apiextensionsv1.JSON{Raw: []byte(raw)}); err == nil {
+		t.Fatal("Parse() error = nil, want an error for an unregistered apiVersion")
+	}
+}
+
+func TestParseRejectsMismatchedKind(t *testing.T) {
+	s := New("Foo")
+	Register[fooV2](s, "example.com/v2")
+
+	raw := `{"apiVersion":"example.com/v2","kind":"Bar"}`
+	if _, err := Parse[fooV2](s, "example.com/v2", &apiextensionsv1.JSON{Raw: []byte(raw)}); err == nil {
+		t.Fatal("Parse() error = nil, want an error for a mismatched kind")
+	}
+}
+
+func TestParseRejectsMissingConversion(t *testing.T) {
+	s := New("Foo")
+	Register[fooV1](s, "example.com/v1")
+	Register[fooV2](s, "example.com/v2")
+	// no RegisterConversion call: v1 -> v2 is not wired up.
+
+	raw := `{"apiVersion":"example.com/v1","kind":"Foo","value":"hello"}`
+	if _, err := Parse[fooV2](s, "example.com/v2", &apiextensionsv1.JSON{Raw: []byte(raw)}); err == nil {
+		t.Fatal("Parse() error = nil, want an error when no conversion is registered")
+	}
+}
+
+func TestParseReturnsNilForNilData(t *testing.T) {
+	s := New("Foo")
+	Register[fooV2](s, "example.com/v2")
+
+	got, err := Parse[fooV2](s, "example.com/v2", nil)
+	if err != nil || got != nil {
+		t.Fatalf("Parse(nil) = %v, %v, want nil, nil", got, err)
+	}
+}